@@ -0,0 +1,318 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DecoderOptions configures the behavior of Unmarshal and Decoder.
+type DecoderOptions struct {
+	// Strict, when true, causes decoding to fail if the INI data contains a
+	// section or option that has no corresponding struct field. When false,
+	// unrecognized sections and options are silently ignored.
+	Strict bool
+}
+
+// Decoder unmarshals INI data into a struct according to DecoderOptions.
+type Decoder struct {
+	opts DecoderOptions
+}
+
+// NewDecoder creates a new Decoder with the specified options.
+func NewDecoder(opts DecoderOptions) *Decoder {
+	return &Decoder{opts: opts}
+}
+
+// Unmarshal populates the fields of v, which must be a pointer to a struct,
+// from the sections and options in ini. A top-level field whose type is a
+// struct is mapped to a section named after its `ini:"section-name"` tag,
+// falling back to the field name. Scalar fields inside that inner struct are
+// mapped to options the same way, using `ini:"option-name"`.
+func Unmarshal(ini *INI, v interface{}) error {
+	return NewDecoder(DecoderOptions{}).Decode(ini, v)
+}
+
+// UnmarshalFile reads the INI file at path and unmarshals it into v.
+func UnmarshalFile(path string, v interface{}) error {
+	ini, err := ReadFile(path, true)
+	if err != nil {
+		return err
+	}
+	return Unmarshal(ini, v)
+}
+
+// Decode populates the fields of v from ini, honoring d's DecoderOptions.
+func (d *Decoder) Decode(ini *INI, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("goini: Unmarshal target must be a non-nil pointer to a struct")
+	}
+	rv = rv.Elem()
+	rt := rv.Type()
+
+	knownSections := map[string]bool{}
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fieldVal := rv.Field(i)
+		if field.Type.Kind() != reflect.Struct {
+			continue
+		}
+		tag := parseIniTag(field.Tag.Get("ini"))
+		if tag.name == "-" {
+			continue
+		}
+		sectionName := tag.name
+		if sectionName == "" {
+			sectionName = field.Name
+		}
+		knownSections[sectionName] = true
+
+		knownOptions := map[string]bool{}
+		innerType := field.Type
+		for j := 0; j < innerType.NumField(); j++ {
+			innerField := innerType.Field(j)
+			innerTag := parseIniTag(innerField.Tag.Get("ini"))
+			if innerTag.name == "-" {
+				continue
+			}
+			optionName := innerTag.name
+			if optionName == "" {
+				optionName = innerField.Name
+			}
+			knownOptions[optionName] = true
+
+			value, found := ini.GetOption(sectionName, optionName)
+			if !found {
+				def, ok := innerField.Tag.Lookup("default")
+				if !ok {
+					continue
+				}
+				value = def
+			}
+			if err := setFieldValue(fieldVal.Field(j), value, innerField.Tag.Get("ini-delim")); err != nil {
+				return fmt.Errorf("goini: cannot unmarshal [%s] %s: %s", sectionName, optionName, err)
+			}
+		}
+
+		if d.opts.Strict {
+			for _, optionName := range ini.Options(sectionName) {
+				if !knownOptions[optionName] {
+					return fmt.Errorf("goini: unknown option %q in section %q", optionName, sectionName)
+				}
+			}
+		}
+	}
+
+	if d.opts.Strict {
+		for _, sectionName := range ini.Sections() {
+			if !knownSections[sectionName] {
+				return fmt.Errorf("goini: unknown section %q", sectionName)
+			}
+		}
+	}
+	return nil
+}
+
+// Marshal builds an *INI from v, which must be a struct or a pointer to a
+// struct, using the same `ini` tag rules as Unmarshal.
+func Marshal(v interface{}) (*INI, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("goini: Marshal target must be a struct or a pointer to a struct")
+	}
+	rt := rv.Type()
+
+	ini := NewINI(true)
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fieldVal := rv.Field(i)
+		if field.Type.Kind() != reflect.Struct {
+			continue
+		}
+		tag := parseIniTag(field.Tag.Get("ini"))
+		if tag.name == "-" {
+			continue
+		}
+		sectionName := tag.name
+		if sectionName == "" {
+			sectionName = field.Name
+		}
+		ini.AddSection(sectionName)
+
+		innerType := field.Type
+		for j := 0; j < innerType.NumField(); j++ {
+			innerField := innerType.Field(j)
+			innerTag := parseIniTag(innerField.Tag.Get("ini"))
+			if innerTag.name == "-" {
+				continue
+			}
+			optionName := innerTag.name
+			if optionName == "" {
+				optionName = innerField.Name
+			}
+			innerVal := fieldVal.Field(j)
+			if innerTag.omitempty && innerVal.IsZero() {
+				continue
+			}
+			strVal, err := fieldValueToString(innerVal, innerField.Tag.Get("ini-delim"))
+			if err != nil {
+				return nil, fmt.Errorf("goini: cannot marshal [%s] %s: %s", sectionName, optionName, err)
+			}
+			ini.AddOption(sectionName, optionName, strVal)
+		}
+	}
+	return ini, nil
+}
+
+// MarshalFile builds an *INI from v and writes it to the file at path.
+func MarshalFile(v interface{}, path string) error {
+	ini, err := Marshal(v)
+	if err != nil {
+		return err
+	}
+	return WriteFile(ini, path)
+}
+
+// iniTag is the parsed form of an `ini:"..."` struct tag.
+type iniTag struct {
+	name      string
+	omitempty bool
+}
+
+func parseIniTag(tag string) iniTag {
+	parts := strings.Split(tag, ",")
+	t := iniTag{name: parts[0]}
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			t.omitempty = true
+		}
+	}
+	return t
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setFieldValue converts value into fv's type and assigns it. delim selects
+// the separator used to split slice values; it defaults to a comma.
+func setFieldValue(fv reflect.Value, value, delim string) error {
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(value)
+	case reflect.Bool:
+		b, err := parseBool(value)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		if delim == "" {
+			delim = ","
+		}
+		parts := splitNonEmpty(value, delim)
+		slice := reflect.MakeSlice(fv.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setFieldValue(slice.Index(i), strings.TrimSpace(part), delim); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
+
+// fieldValueToString is the inverse of setFieldValue, used by Marshal.
+func fieldValueToString(fv reflect.Value, delim string) (string, error) {
+	if fv.Type() == durationType {
+		return time.Duration(fv.Int()).String(), nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		return fv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(fv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(fv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(fv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(fv.Float(), 'g', -1, 64), nil
+	case reflect.Slice:
+		if delim == "" {
+			delim = ","
+		}
+		values := make([]string, fv.Len())
+		for i := 0; i < fv.Len(); i++ {
+			s, err := fieldValueToString(fv.Index(i), delim)
+			if err != nil {
+				return "", err
+			}
+			values[i] = s
+		}
+		return strings.Join(values, delim), nil
+	default:
+		return "", fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+}
+
+func splitNonEmpty(s, sep string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, sep)
+}
@@ -0,0 +1,137 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"testing"
+	"time"
+)
+
+type testServer struct {
+	Name    string        `ini:"name"`
+	Timeout time.Duration `ini:"timeout"`
+}
+
+type testConfig struct {
+	Server testServer        `ini:"server"`
+	Main   testMainSection   `ini:"main"`
+	Hidden testHiddenSection `ini:"-"`
+}
+
+type testMainSection struct {
+	Debug bool     `ini:"debug" default:"false"`
+	Ports []int    `ini:"ports"`
+	Tags  []string `ini:"tags" ini-delim:";"`
+	Ratio float64  `ini:"ratio,omitempty"`
+}
+
+type testHiddenSection struct {
+	Secret string `ini:"secret"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("server", "name", "localhost")
+	ini.AddOption("server", "timeout", "5s")
+	ini.AddOption("main", "ports", "80,443,8080")
+	ini.AddOption("main", "tags", "a;b;c")
+
+	var cfg testConfig
+	err := Unmarshal(ini, &cfg)
+	assertNoError(t, err)
+	assertEquals(t, "localhost", cfg.Server.Name)
+	assertEquals(t, 5*time.Second, cfg.Server.Timeout)
+	assertEquals(t, false, cfg.Main.Debug)
+	assertEquals(t, 3, len(cfg.Main.Ports))
+	assertEquals(t, 80, cfg.Main.Ports[0])
+	assertEquals(t, 443, cfg.Main.Ports[1])
+	assertEquals(t, 8080, cfg.Main.Ports[2])
+	assertEquals(t, 3, len(cfg.Main.Tags))
+	assertEquals(t, "b", cfg.Main.Tags[1])
+}
+
+func TestUnmarshalDefault(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("server", "name", "localhost")
+
+	var cfg testConfig
+	err := Unmarshal(ini, &cfg)
+	assertNoError(t, err)
+	assertEquals(t, false, cfg.Main.Debug)
+}
+
+func TestUnmarshalStrict(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("server", "name", "localhost")
+	ini.AddOption("server", "bogus", "x")
+
+	var cfg testConfig
+	err := NewDecoder(DecoderOptions{Strict: true}).Decode(ini, &cfg)
+	assertError(t, err)
+}
+
+func TestUnmarshalStrictUnknownSection(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("server", "name", "localhost")
+	ini.AddOption("bogus", "x", "y")
+
+	var cfg testConfig
+	err := NewDecoder(DecoderOptions{Strict: true}).Decode(ini, &cfg)
+	assertError(t, err)
+}
+
+func TestMarshal(t *testing.T) {
+	cfg := testConfig{
+		Server: testServer{Name: "localhost", Timeout: 5 * time.Second},
+		Main:   testMainSection{Debug: true, Ports: []int{80, 443}, Tags: []string{"a", "b"}},
+	}
+	ini, err := Marshal(&cfg)
+	assertNoError(t, err)
+
+	val, found := ini.GetOption("server", "name")
+	assertTrue(t, found)
+	assertEquals(t, "localhost", val)
+
+	val, found = ini.GetOption("server", "timeout")
+	assertTrue(t, found)
+	assertEquals(t, "5s", val)
+
+	val, found = ini.GetOption("main", "ports")
+	assertTrue(t, found)
+	assertEquals(t, "80,443", val)
+
+	val, found = ini.GetOption("main", "tags")
+	assertTrue(t, found)
+	assertEquals(t, "a;b", val)
+
+	assertFalse(t, ini.HasSection("Hidden"))
+}
+
+func TestMarshalOmitempty(t *testing.T) {
+	cfg := testConfig{Server: testServer{Name: "localhost"}}
+	ini, err := Marshal(&cfg)
+	assertNoError(t, err)
+
+	_, found := ini.GetOption("main", "ratio")
+	assertFalse(t, found)
+}
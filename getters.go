@@ -0,0 +1,216 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GetString gets the option's value as a string. It returns an error if the
+// section or option does not exist.
+func (ini *INI) GetString(sectionName, optionName string) (string, error) {
+	value, found := ini.GetOption(sectionName, optionName)
+	if !found {
+		return "", fmt.Errorf("goini: option %q not found in section %q", optionName, sectionName)
+	}
+	return value, nil
+}
+
+// MustGetString is like GetString, but returns def instead of an error.
+func (ini *INI) MustGetString(sectionName, optionName, def string) string {
+	value, err := ini.GetString(sectionName, optionName)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetInt gets the option's value parsed as an int.
+func (ini *INI) GetInt(sectionName, optionName string) (int, error) {
+	value, err := ini.GetString(sectionName, optionName)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseInt(value, 10, 0)
+	if err != nil {
+		return 0, err
+	}
+	return int(n), nil
+}
+
+// MustGetInt is like GetInt, but returns def instead of an error.
+func (ini *INI) MustGetInt(sectionName, optionName string, def int) int {
+	value, err := ini.GetInt(sectionName, optionName)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetInt64 gets the option's value parsed as an int64.
+func (ini *INI) GetInt64(sectionName, optionName string) (int64, error) {
+	value, err := ini.GetString(sectionName, optionName)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// MustGetInt64 is like GetInt64, but returns def instead of an error.
+func (ini *INI) MustGetInt64(sectionName, optionName string, def int64) int64 {
+	value, err := ini.GetInt64(sectionName, optionName)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetUint gets the option's value parsed as a uint.
+func (ini *INI) GetUint(sectionName, optionName string) (uint, error) {
+	value, err := ini.GetString(sectionName, optionName)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return 0, err
+	}
+	return uint(n), nil
+}
+
+// MustGetUint is like GetUint, but returns def instead of an error.
+func (ini *INI) MustGetUint(sectionName, optionName string, def uint) uint {
+	value, err := ini.GetUint(sectionName, optionName)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetFloat gets the option's value parsed as a float64.
+func (ini *INI) GetFloat(sectionName, optionName string) (float64, error) {
+	value, err := ini.GetString(sectionName, optionName)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+// MustGetFloat is like GetFloat, but returns def instead of an error.
+func (ini *INI) MustGetFloat(sectionName, optionName string, def float64) float64 {
+	value, err := ini.GetFloat(sectionName, optionName)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetBool gets the option's value parsed as a bool. It accepts, case
+// insensitively: true/false, yes/no, on/off, and 1/0.
+func (ini *INI) GetBool(sectionName, optionName string) (bool, error) {
+	value, err := ini.GetString(sectionName, optionName)
+	if err != nil {
+		return false, err
+	}
+	return parseBool(value)
+}
+
+// MustGetBool is like GetBool, but returns def instead of an error.
+func (ini *INI) MustGetBool(sectionName, optionName string, def bool) bool {
+	value, err := ini.GetBool(sectionName, optionName)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetDuration gets the option's value parsed with time.ParseDuration.
+func (ini *INI) GetDuration(sectionName, optionName string) (time.Duration, error) {
+	value, err := ini.GetString(sectionName, optionName)
+	if err != nil {
+		return 0, err
+	}
+	return time.ParseDuration(value)
+}
+
+// MustGetDuration is like GetDuration, but returns def instead of an error.
+func (ini *INI) MustGetDuration(sectionName, optionName string, def time.Duration) time.Duration {
+	value, err := ini.GetDuration(sectionName, optionName)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetTime gets the option's value parsed with time.Parse using layout.
+func (ini *INI) GetTime(sectionName, optionName, layout string) (time.Time, error) {
+	value, err := ini.GetString(sectionName, optionName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(layout, value)
+}
+
+// MustGetTime is like GetTime, but returns def instead of an error.
+func (ini *INI) MustGetTime(sectionName, optionName, layout string, def time.Time) time.Time {
+	value, err := ini.GetTime(sectionName, optionName, layout)
+	if err != nil {
+		return def
+	}
+	return value
+}
+
+// GetStringSlice splits the option's value on sep, trimming surrounding
+// whitespace from each element, e.g. "a, b,c" with sep "," becomes
+// ["a", "b", "c"]. Unlike GetOptionValues, this splits a single value
+// rather than returning separately-added ones.
+func (ini *INI) GetStringSlice(sectionName, optionName, sep string) ([]string, error) {
+	value, err := ini.GetString(sectionName, optionName)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return []string{}, nil
+	}
+	parts := strings.Split(value, sep)
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts, nil
+}
+
+// parseBool accepts the go-ini-style boolean spellings, case insensitively:
+// true/false, yes/no, on/off, 1/0.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0":
+		return false, nil
+	default:
+		return false, fmt.Errorf("goini: invalid boolean value %q", value)
+	}
+}
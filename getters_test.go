@@ -0,0 +1,173 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTypedGetters(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "str", "hello")
+	ini.AddOption("section1", "int", "-42")
+	ini.AddOption("section1", "int64", "9223372036854775807")
+	ini.AddOption("section1", "uint", "42")
+	ini.AddOption("section1", "float", "3.14")
+	ini.AddOption("section1", "bool", "yes")
+	ini.AddOption("section1", "duration", "1h30m")
+	ini.AddOption("section1", "time", "2021-01-02")
+
+	str, err := ini.GetString("section1", "str")
+	assertNoError(t, err)
+	assertEquals(t, "hello", str)
+
+	i, err := ini.GetInt("section1", "int")
+	assertNoError(t, err)
+	assertEquals(t, -42, i)
+
+	i64, err := ini.GetInt64("section1", "int64")
+	assertNoError(t, err)
+	assertEquals(t, int64(9223372036854775807), i64)
+
+	u, err := ini.GetUint("section1", "uint")
+	assertNoError(t, err)
+	assertEquals(t, uint(42), u)
+
+	f, err := ini.GetFloat("section1", "float")
+	assertNoError(t, err)
+	assertEquals(t, 3.14, f)
+
+	b, err := ini.GetBool("section1", "bool")
+	assertNoError(t, err)
+	assertTrue(t, b)
+
+	d, err := ini.GetDuration("section1", "duration")
+	assertNoError(t, err)
+	assertEquals(t, 90*time.Minute, d)
+
+	tm, err := ini.GetTime("section1", "time", "2006-01-02")
+	assertNoError(t, err)
+	assertEquals(t, 2021, tm.Year())
+
+	_, err = ini.GetString("section1", "missing")
+	assertError(t, err)
+}
+
+func TestMustGetters(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "int", "42")
+
+	assertEquals(t, 42, ini.MustGetInt("section1", "int", -1))
+	assertEquals(t, -1, ini.MustGetInt("section1", "missing", -1))
+	assertEquals(t, "fallback", ini.MustGetString("section1", "missing", "fallback"))
+	assertEquals(t, true, ini.MustGetBool("section1", "missing", true))
+	assertEquals(t, 5*time.Second, ini.MustGetDuration("section1", "missing", 5*time.Second))
+}
+
+func TestGetBoolAcceptsGoIniSpellings(t *testing.T) {
+	ini := NewINI(true)
+	for _, spelling := range []string{"true", "TRUE", "yes", "YES", "on", "ON", "1"} {
+		ini.AddOption("section1", "flag", spelling)
+		b, err := ini.GetBool("section1", "flag")
+		assertNoError(t, err)
+		assertTrue(t, b)
+	}
+	for _, spelling := range []string{"false", "FALSE", "no", "NO", "off", "OFF", "0"} {
+		ini.AddOption("section1", "flag", spelling)
+		b, err := ini.GetBool("section1", "flag")
+		assertNoError(t, err)
+		assertFalse(t, b)
+	}
+
+	_, err := func() (bool, error) {
+		ini.AddOption("section1", "flag", "nope")
+		return ini.GetBool("section1", "flag")
+	}()
+	assertError(t, err)
+}
+
+func TestGetStringSlice(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "tags", "a, b,  c")
+
+	slice, err := ini.GetStringSlice("section1", "tags", ",")
+	assertNoError(t, err)
+	assertEquals(t, 3, len(slice))
+	assertEquals(t, "a", slice[0])
+	assertEquals(t, "b", slice[1])
+	assertEquals(t, "c", slice[2])
+}
+
+func TestAddOptionMultiRetainsAllValues(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOptionMulti("section1", "tag", "one")
+	ini.AddOptionMulti("section1", "tag", "two")
+	ini.AddOptionMulti("section1", "tag", "three")
+
+	values, found := ini.GetOptionValues("section1", "tag")
+	assertTrue(t, found)
+	assertEquals(t, 3, len(values))
+	assertEquals(t, "one", values[0])
+	assertEquals(t, "two", values[1])
+	assertEquals(t, "three", values[2])
+
+	value, found := ini.GetOption("section1", "tag")
+	assertTrue(t, found)
+	assertEquals(t, "three", value)
+}
+
+func TestAddOptionCollapsesPriorMultiValues(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOptionMulti("section1", "tag", "one")
+	ini.AddOptionMulti("section1", "tag", "two")
+	ini.AddOption("section1", "tag", "reset")
+
+	values, found := ini.GetOptionValues("section1", "tag")
+	assertTrue(t, found)
+	assertEquals(t, 1, len(values))
+	assertEquals(t, "reset", values[0])
+
+	var sb strings.Builder
+	err := Write(ini, &sb)
+	assertNoError(t, err)
+	assertEquals(t, 1, strings.Count(sb.String(), "tag"))
+}
+
+func TestParserRetainsDuplicateKeysAsMultiValue(t *testing.T) {
+	data := "[section1]\ntag = one\ntag = two\n"
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	values, found := ini.GetOptionValues("section1", "tag")
+	assertTrue(t, found)
+	assertEquals(t, 2, len(values))
+	assertEquals(t, "one", values[0])
+	assertEquals(t, "two", values[1])
+
+	var sb strings.Builder
+	err = Write(ini, &sb)
+	assertNoError(t, err)
+	assertEquals(t, data, sb.String())
+}
@@ -23,42 +23,91 @@
 package goini
 
 import (
-	"bufio"
 	"fmt"
-	"io"
-	"os"
-	"regexp"
-	"strings"
 )
 
-var (
-	sectionRegex = regexp.MustCompile(`^\[(.*)\]$`)
-	assignRegex  = regexp.MustCompile(`^([^=]+)=(.*)$`)
-)
-
-// ErrSyntax is returned when there is a syntax error in an INI file.
+// ErrSyntax is returned when there is a syntax error in an INI file. File is
+// the name of the file the error came from; it is empty when parsing from an
+// io.Reader that is not associated with a file (or an included file).
 type ErrSyntax struct {
+	File   string
 	Line   int
 	Source string // The contents of the erroneous line, without leading or trailing whitespace
 }
 
 func (e ErrSyntax) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s: invalid INI syntax on line %d: %s", e.File, e.Line, e.Source)
+	}
 	return fmt.Sprintf("invalid INI syntax on line %d: %s", e.Line, e.Source)
 }
 
+// nodeKind identifies the kind of line represented by a node in an INI's
+// ordered element list.
+type nodeKind int
+
+const (
+	blankNode nodeKind = iota
+	commentNode
+	sectionNode
+	optionNode
+)
+
+// node is one line-level element of an INI file: a blank line, a standalone
+// comment, a "[section]" header, or a "key = value" option. Options and
+// sections reference their current value/comment through INI's section
+// maps rather than storing it directly, so that mutating or removing an
+// entry doesn't require renumbering the comments and blank lines around it.
+type node struct {
+	kind       nodeKind
+	text       string // raw comment text for commentNode, e.g. "; some comment"
+	section    string // section name for sectionNode and optionNode
+	option     string // option name for optionNode
+	valueIndex int    // which of option's (possibly multiple) values this node renders; see AddOptionMulti
+
+	// origLine and origValue are set by the parser to the option's source
+	// line (with surrounding whitespace trimmed, and continuation lines
+	// already joined) and the value it held right after parsing. Write emits
+	// origLine unchanged as long as the option's current value still equals
+	// origValue, so an option nobody touched keeps its original quoting style
+	// and spacing around the separator instead of being re-serialized
+	// through KeyValueSeparator/quoteIfNeeded. Leading indentation and line
+	// continuations are not reproduced. Both are empty
+	// for options added or changed programmatically, which always go
+	// through normalization.
+	origLine  string
+	origValue string
+}
+
 // INI is a struct that represents a parsed INI file.
 type INI struct {
-	ordered      bool
-	sectionNames []string
-	sections     map[string]*iniOptions
+	ordered         bool
+	sectionNames    []string
+	sections        map[string]*iniOptions
+	sectionComments map[string]string
+	nodes           []*node
+
+	// DefaultSection is the name of the section whose options are used as a
+	// fallback by GetOption/GetOptionRaw/HasOption when a lookup in the
+	// requested section misses. It defaults to "DEFAULT".
+	DefaultSection string
+
+	interpolate    bool
+	recursionLimit int
 }
 
+// defaultSectionName is the DefaultSection value a new INI starts with.
+const defaultSectionName = "DEFAULT"
+
 // NewINI creates a new INI.
 func NewINI(ordered bool) *INI {
 	return &INI{
-		ordered:      ordered,
-		sectionNames: []string{},
-		sections:     map[string]*iniOptions{},
+		ordered:         ordered,
+		sectionNames:    []string{},
+		sections:        map[string]*iniOptions{},
+		sectionComments: map[string]string{},
+		nodes:           []*node{},
+		DefaultSection:  defaultSectionName,
 	}
 }
 
@@ -68,13 +117,11 @@ func (ini *INI) HasSection(sectionName string) bool {
 	return found
 }
 
-// HasOption checks if the specified section and option names exist.
+// HasOption checks if the specified section and option names exist, falling
+// back to DefaultSection when sectionName itself does not define optionName.
 func (ini *INI) HasOption(sectionName, optionName string) bool {
-	if !ini.HasSection(sectionName) {
-		return false
-	}
-	opts := ini.sections[sectionName]
-	return opts.exist(optionName)
+	_, found := ini.getRaw(sectionName, optionName)
+	return found
 }
 
 // AddSection add a new section. This method returns true if the section name can be
@@ -86,6 +133,7 @@ func (ini *INI) AddSection(sectionName string) bool {
 		if ini.ordered {
 			ini.sectionNames = append(ini.sectionNames, sectionName)
 		}
+		ini.nodes = append(ini.nodes, &node{kind: sectionNode, section: sectionName})
 		return true
 	}
 	return false
@@ -99,13 +147,213 @@ func (ini *INI) AddOption(sectionName, optionName, optionValue string) bool {
 		ini.AddSection(sectionName)
 	}
 	opts := ini.sections[sectionName]
-	return opts.add(optionName, optionValue)
+	isNewOption := !opts.exist(optionName)
+	added := opts.add(optionName, optionValue)
+	if isNewOption {
+		ini.insertOptionNode(sectionName, optionName)
+	} else {
+		ini.collapseOptionNodes(sectionName, optionName)
+	}
+	return added
 }
 
-// GetOption gets the option value from specified section and option names. If a section
-// name does not exist, this method will return false.
+// AddOptionMulti appends optionValue as an additional value for optionName
+// in sectionName, retaining any values previously recorded for that option
+// (by AddOption or AddOptionMulti) instead of overwriting them, as the same
+// key appearing more than once in a parsed file does. If a section name
+// does not exist, it will be automatically created. GetOption and
+// GetOptionRaw return the most recently added value; GetOptionValues
+// returns all of them, and Write emits one line per value in the order they
+// were added.
+func (ini *INI) AddOptionMulti(sectionName, optionName, optionValue string) bool {
+	if !ini.HasSection(sectionName) {
+		ini.AddSection(sectionName)
+	}
+	opts := ini.sections[sectionName]
+	valueIndex := opts.addMulti(optionName, optionValue)
+	ini.insertOptionNodeAt(sectionName, optionName, valueIndex)
+	return true
+}
+
+// addParsedOption is AddOptionMulti plus bookkeeping used only by the
+// parser: it records origLine, the option's trimmed source line, so Write
+// can reproduce it unchanged as long as the option is never mutated (see
+// node.origLine).
+func (ini *INI) addParsedOption(sectionName, optionName, optionValue, origLine string) {
+	if !ini.HasSection(sectionName) {
+		ini.AddSection(sectionName)
+	}
+	opts := ini.sections[sectionName]
+	valueIndex := opts.addMulti(optionName, optionValue)
+	ini.insertParsedOptionNode(sectionName, optionName, valueIndex, origLine, optionValue)
+}
+
+// collapseOptionNodes keeps only the first node recorded for sectionName's
+// optionName, discarding any extras left over from a prior AddOptionMulti.
+// It runs when a plain AddOption overwrites a multi-valued option back down
+// to a single value.
+func (ini *INI) collapseOptionNodes(sectionName, optionName string) {
+	kept := false
+	filtered := ini.nodes[:0]
+	for _, n := range ini.nodes {
+		if n.kind == optionNode && n.section == sectionName && n.option == optionName {
+			if kept {
+				continue
+			}
+			kept = true
+			n.valueIndex = 0
+		}
+		filtered = append(filtered, n)
+	}
+	ini.nodes = filtered
+}
+
+// insertOptionNode records a newly added option in ini.nodes, positioned at
+// the end of its section's existing elements so that Write groups it with
+// the rest of that section.
+func (ini *INI) insertOptionNode(sectionName, optionName string) {
+	ini.insertOptionNodeAt(sectionName, optionName, 0)
+}
+
+// insertOptionNodeAt is like insertOptionNode, but records which of
+// optionName's (possibly multiple) values this node renders.
+func (ini *INI) insertOptionNodeAt(sectionName, optionName string, valueIndex int) {
+	ini.insertParsedOptionNode(sectionName, optionName, valueIndex, "", "")
+}
+
+// insertParsedOptionNode is like insertOptionNodeAt, additionally recording
+// the option's trimmed source line and the value it held at parse time, so
+// Write can reproduce origLine unchanged until the option is mutated.
+func (ini *INI) insertParsedOptionNode(sectionName, optionName string, valueIndex int, origLine, origValue string) {
+	newNode := &node{
+		kind:       optionNode,
+		section:    sectionName,
+		option:     optionName,
+		valueIndex: valueIndex,
+		origLine:   origLine,
+		origValue:  origValue,
+	}
+	start := -1
+	for i, n := range ini.nodes {
+		if n.kind == sectionNode && n.section == sectionName {
+			start = i
+			break
+		}
+	}
+	if start == -1 {
+		ini.nodes = append(ini.nodes, newNode)
+		return
+	}
+	insertAt := len(ini.nodes)
+	for i := start + 1; i < len(ini.nodes); i++ {
+		if ini.nodes[i].kind == sectionNode {
+			insertAt = i
+			break
+		}
+	}
+	ini.nodes = append(ini.nodes, nil)
+	copy(ini.nodes[insertAt+1:], ini.nodes[insertAt:])
+	ini.nodes[insertAt] = newNode
+}
+
+// GetOption gets the option value from specified section and option names,
+// falling back to DefaultSection when sectionName does not define
+// optionName. If a section name does not exist, this method will return
+// false. When interpolation has been enabled (see ParserOptions.Interpolate
+// or Expand), ${...} and %(...)s references in the value are resolved; if
+// that resolution fails (e.g. a reference cycle, recursion limit, or a
+// reference to a nonexistent option), GetOption returns true along with the
+// raw, unexpanded value rather than failing the lookup — a typo'd
+// reference is therefore not distinguishable from a successful expansion by
+// the returned bool alone. Callers that need to detect a resolution failure
+// must use ExpandOption instead, which returns the error.
 func (ini *INI) GetOption(sectionName, optionName string) (string, bool) {
+	value, found := ini.getRaw(sectionName, optionName)
+	if !found {
+		return "", false
+	}
+	if !ini.interpolate {
+		return value, true
+	}
+	if expanded, err := ini.expandValue(sectionName, value, nil, 0); err == nil {
+		return expanded, true
+	}
+	return value, true
+}
+
+// GetOptionRaw gets the option value exactly as stored, without
+// interpolating any ${...} or %(...)s references, but still falling back to
+// DefaultSection when sectionName does not define optionName.
+func (ini *INI) GetOptionRaw(sectionName, optionName string) (string, bool) {
+	return ini.getRaw(sectionName, optionName)
+}
+
+// GetOptionValues returns every value recorded for optionName in
+// sectionName, in the order they were added. Unlike GetOption, which
+// returns only the most recently added value, this surfaces all of them
+// when the same key was added more than once via AddOptionMulti (as
+// happens when a parsed file repeats a key). It falls back to
+// DefaultSection when sectionName itself does not define optionName.
+func (ini *INI) GetOptionValues(sectionName, optionName string) ([]string, bool) {
+	if opts, ok := ini.sections[sectionName]; ok {
+		if values, found := opts.getValues(optionName); found {
+			return values, true
+		}
+	}
+	if sectionName == ini.DefaultSection {
+		return nil, false
+	}
+	if opts, ok := ini.sections[ini.DefaultSection]; ok {
+		return opts.getValues(optionName)
+	}
+	return nil, false
+}
+
+// optionValueAt returns the valueIndex'th value recorded for optionName in
+// sectionName, as addressed by an optionNode. It is used by Write so that a
+// multi-valued option round-trips every occurrence.
+func (ini *INI) optionValueAt(sectionName, optionName string, valueIndex int) (string, bool) {
+	opts, ok := ini.sections[sectionName]
+	if !ok {
+		return "", false
+	}
+	return opts.getValueAt(optionName, valueIndex)
+}
+
+// ExpandOption is like GetOption, but surfaces an error rather than
+// silently falling back to the raw value when interpolation fails.
+func (ini *INI) ExpandOption(sectionName, optionName string) (string, bool, error) {
+	value, found := ini.getRaw(sectionName, optionName)
+	if !found {
+		return "", false, nil
+	}
+	expanded, err := ini.expandValue(sectionName, value, nil, 0)
+	if err != nil {
+		return value, true, err
+	}
+	return expanded, true, nil
+}
+
+// Expand enables ${...} and %(...)s interpolation for subsequent GetOption
+// calls. It is the programmatic equivalent of setting
+// ParserOptions.Interpolate before parsing. ExpandOption interpolates
+// whether or not Expand has been called.
+func (ini *INI) Expand() {
+	ini.interpolate = true
+}
+
+// getRaw looks up optionName in sectionName, falling back to DefaultSection
+// when sectionName itself does not define it.
+func (ini *INI) getRaw(sectionName, optionName string) (string, bool) {
 	if opts, ok := ini.sections[sectionName]; ok {
+		if value, found := opts.get(optionName); found {
+			return value, true
+		}
+	}
+	if sectionName == ini.DefaultSection {
+		return "", false
+	}
+	if opts, ok := ini.sections[ini.DefaultSection]; ok {
 		return opts.get(optionName)
 	}
 	return "", false
@@ -119,6 +367,7 @@ func (ini *INI) RemoveSection(sectionName string) bool {
 		return false
 	}
 	delete(ini.sections, sectionName)
+	delete(ini.sectionComments, sectionName)
 	if ini.ordered {
 		i := 0
 		for idx, name := range ini.sectionNames {
@@ -128,9 +377,34 @@ func (ini *INI) RemoveSection(sectionName string) bool {
 		}
 		ini.sectionNames = append(ini.sectionNames[:i], ini.sectionNames[i+1:]...)
 	}
+	ini.removeSectionNodes(sectionName)
 	return true
 }
 
+// removeSectionNodes strips the sectionNode for sectionName along with every
+// node between it and the next section header (its options, comments and
+// blank lines).
+func (ini *INI) removeSectionNodes(sectionName string) {
+	start := -1
+	end := len(ini.nodes)
+	for i, n := range ini.nodes {
+		if start == -1 {
+			if n.kind == sectionNode && n.section == sectionName {
+				start = i
+			}
+			continue
+		}
+		if n.kind == sectionNode {
+			end = i
+			break
+		}
+	}
+	if start == -1 {
+		return
+	}
+	ini.nodes = append(ini.nodes[:start], ini.nodes[end:]...)
+}
+
 // RemoveOption removes the specified the option name from the specified section name.
 // This method returns true if the option name can be successfully removed. It returns
 // false if the section name or option name does exist.
@@ -139,7 +413,21 @@ func (ini *INI) RemoveOption(sectionName, optionName string) bool {
 		return false
 	}
 	opts := ini.sections[sectionName]
-	return opts.remove(optionName)
+	removed := opts.remove(optionName)
+	if removed {
+		delete(opts.optionComments, optionName)
+		ini.removeOptionNode(sectionName, optionName)
+	}
+	return removed
+}
+
+func (ini *INI) removeOptionNode(sectionName, optionName string) {
+	for i, n := range ini.nodes {
+		if n.kind == optionNode && n.section == sectionName && n.option == optionName {
+			ini.nodes = append(ini.nodes[:i], ini.nodes[i+1:]...)
+			return
+		}
+	}
 }
 
 // Sections returns a list of section names.
@@ -162,19 +450,70 @@ func (ini *INI) Options(sectionName string) []string {
 	return ini.sections[sectionName].getOptions()
 }
 
-// iniOptions is a struct that represents INI options.
+// SectionComment returns the leading comment attached to the specified
+// section, or "" if the section has none.
+func (ini *INI) SectionComment(sectionName string) string {
+	return ini.sectionComments[sectionName]
+}
+
+// SetSectionComment attaches comment as the leading comment written
+// directly above the specified section's "[section]" header. If the
+// section does not exist yet, it is created.
+func (ini *INI) SetSectionComment(sectionName, comment string) {
+	if !ini.HasSection(sectionName) {
+		ini.AddSection(sectionName)
+	}
+	ini.sectionComments[sectionName] = comment
+}
+
+// OptionComment returns the leading comment attached to the specified
+// option, or "" if it has none.
+func (ini *INI) OptionComment(sectionName, optionName string) string {
+	if opts, ok := ini.sections[sectionName]; ok {
+		return opts.optionComments[optionName]
+	}
+	return ""
+}
+
+// SetOptionComment attaches comment as the leading comment written directly
+// above the specified option. If the section does not exist yet, it is
+// created.
+func (ini *INI) SetOptionComment(sectionName, optionName, comment string) {
+	if !ini.HasSection(sectionName) {
+		ini.AddSection(sectionName)
+	}
+	ini.sections[sectionName].optionComments[optionName] = comment
+}
+
+// appendBlankNode records a blank line at the current write position.
+func (ini *INI) appendBlankNode() {
+	ini.nodes = append(ini.nodes, &node{kind: blankNode})
+}
+
+// appendCommentNode records a standalone comment line, i.e. one that is not
+// immediately attached to a following section or option.
+func (ini *INI) appendCommentNode(text string) {
+	ini.nodes = append(ini.nodes, &node{kind: commentNode, text: text})
+}
+
+// iniOptions is a struct that represents INI options. options stores a
+// slice per name rather than a single value so that a key added more than
+// once (see AddOptionMulti) retains every value instead of the last one
+// overwriting the rest.
 type iniOptions struct {
-	ordered     bool
-	optionNames []string
-	options     map[string]string
+	ordered        bool
+	optionNames    []string
+	options        map[string][]string
+	optionComments map[string]string
 }
 
 // newOptions creates a new option.
 func newOptions(ordered bool) *iniOptions {
 	return &iniOptions{
-		ordered:     ordered,
-		optionNames: []string{},
-		options:     map[string]string{},
+		ordered:        ordered,
+		optionNames:    []string{},
+		options:        map[string][]string{},
+		optionComments: map[string]string{},
 	}
 }
 
@@ -184,25 +523,62 @@ func (opts *iniOptions) exist(optionName string) bool {
 	return found
 }
 
-// add adds a new option. This method returns true if the option can be successfully added.
-// It returns false if the option already exists.
+// add adds a new option, or overwrites every previously recorded value of
+// an existing one with optionValue. This method returns true if the option
+// can be successfully added.
 func (opts *iniOptions) add(optionName, optionValue string) bool {
 	if opts.ordered {
 		if !opts.exist(optionName) {
 			opts.optionNames = append(opts.optionNames, optionName)
 		}
 	}
-	opts.options[optionName] = optionValue
+	opts.options[optionName] = []string{optionValue}
 	return true
 }
 
-// get gets the option value from the specified option name. If the specified option name
-// does not exist, this method will return false.
+// addMulti appends optionValue to optionName's recorded values instead of
+// overwriting them, creating the option if it doesn't exist yet. It returns
+// the index of the newly appended value.
+func (opts *iniOptions) addMulti(optionName, optionValue string) int {
+	if opts.ordered {
+		if !opts.exist(optionName) {
+			opts.optionNames = append(opts.optionNames, optionName)
+		}
+	}
+	opts.options[optionName] = append(opts.options[optionName], optionValue)
+	return len(opts.options[optionName]) - 1
+}
+
+// get gets the most recently added value for the specified option name. If
+// the specified option name does not exist, this method will return false.
 func (opts *iniOptions) get(optionName string) (string, bool) {
-	if !opts.exist(optionName) {
+	values, found := opts.options[optionName]
+	if !found || len(values) == 0 {
 		return "", false
 	}
-	return opts.options[optionName], true
+	return values[len(values)-1], true
+}
+
+// getValues returns every value recorded for optionName, in the order they
+// were added, or false if optionName does not exist.
+func (opts *iniOptions) getValues(optionName string) ([]string, bool) {
+	values, found := opts.options[optionName]
+	if !found {
+		return nil, false
+	}
+	out := make([]string, len(values))
+	copy(out, values)
+	return out, true
+}
+
+// getValueAt returns the value at the specified index for optionName, or
+// false if optionName does not exist or index is out of range.
+func (opts *iniOptions) getValueAt(optionName string, index int) (string, bool) {
+	values, found := opts.options[optionName]
+	if !found || index < 0 || index >= len(values) {
+		return "", false
+	}
+	return values[index], true
 }
 
 // remove removes the specified option name. This method returns true if the specified
@@ -236,87 +612,3 @@ func (opts *iniOptions) getOptions() []string {
 	}
 	return opts.optionNames
 }
-
-// Read reads an INI from an io.Reader. Passing ordered parameter true will preserve the
-// order. Preserving the order will have some performance overhead.
-func Read(reader io.Reader, ordered bool) (*INI, error) {
-	ini := NewINI(ordered)
-	bufin, ok := reader.(*bufio.Reader)
-	if !ok {
-		bufin = bufio.NewReader(reader)
-	}
-	err := parse(bufin, ini)
-	return ini, err
-}
-
-// ReadFile reads an INI from a file. Passing ordered parameter true will preserve the
-// order. Preserving the order will have some performance overhead.
-func ReadFile(path string, ordered bool) (*INI, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-	return Read(file, ordered)
-}
-
-// Write writes an INI into an io.Writer.
-func Write(ini *INI, writer io.Writer) error {
-	for _, section := range ini.Sections() {
-		fmt.Fprintln(writer, "["+section+"]")
-		for _, option := range ini.Options(section) {
-			value, _ := ini.GetOption(section, option)
-			fmt.Fprintln(writer, option, "=", value)
-		}
-		fmt.Fprintln(writer)
-	}
-	return nil
-}
-
-// WriteFile writes an INI into a file.
-func WriteFile(ini *INI, path string) error {
-	file, err := os.Create(path)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-	return Write(ini, file)
-}
-
-func parse(reader *bufio.Reader, ini *INI) error {
-	section := ""
-	lineNum := 0
-	for done := false; !done; {
-		var line string
-		var err error
-		if line, err = reader.ReadString('\n'); err != nil {
-			if err == io.EOF {
-				done = true
-			}
-		}
-		lineNum++
-		line = strings.TrimSpace(line)
-		if len(line) == 0 {
-			// Skip blank lines
-			continue
-		}
-		if line[0] == ';' || line[0] == '#' {
-			// Skip comments
-			continue
-		}
-
-		if groups := assignRegex.FindStringSubmatch(line); groups != nil {
-			key, val := groups[1], groups[2]
-			key, val = strings.TrimSpace(key), strings.TrimSpace(val)
-			ini.AddOption(section, key, val)
-		} else if groups := sectionRegex.FindStringSubmatch(line); groups != nil {
-			name := strings.TrimSpace(groups[1])
-			section = name
-			// Create the section if it does not exist
-			ini.AddSection(section)
-		} else {
-			return ErrSyntax{lineNum, line}
-		}
-	}
-	return nil
-}
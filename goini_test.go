@@ -25,6 +25,7 @@ package goini
 import (
 	"reflect"
 	"runtime/debug"
+	"strings"
 	"testing"
 )
 
@@ -292,9 +293,30 @@ func TestINIUnordered(t *testing.T) {
 }
 
 func TestReadWriteOrdered(t *testing.T) {
-	// TODO
+	data := "[section1]\n" +
+		"option1 = value1\n" +
+		"option2 = value2\n" +
+		"\n" +
+		"[section2]\n" +
+		"option1 = value1\n"
+
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	var sb strings.Builder
+	err = Write(ini, &sb)
+	assertNoError(t, err)
+	assertEquals(t, data, sb.String())
 }
 
 func TestReadWriteUnordered(t *testing.T) {
-	// TODO
+	data := "[section1]\noption1 = value1\n\n"
+
+	ini, err := Read(strings.NewReader(data), false)
+	assertNoError(t, err)
+
+	var sb strings.Builder
+	err = Write(ini, &sb)
+	assertNoError(t, err)
+	assertEquals(t, data, sb.String())
 }
@@ -0,0 +1,116 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// defaultRecursionLimit bounds how many nested ${...}/%(...)s references are
+// followed before expandValue gives up.
+const defaultRecursionLimit = 10
+
+var (
+	// braceRefRegex matches "${...}", e.g. "${option}", "${section:option}"
+	// or "${env:VAR}".
+	braceRefRegex = regexp.MustCompile(`\$\{([^}]*)\}`)
+	// percentRefRegex matches the Python-ConfigParser-style "%(name)s".
+	percentRefRegex = regexp.MustCompile(`%\(([^)]+)\)s`)
+)
+
+// expandValue resolves every ${...} and %(...)s reference in value. Bare
+// references (${option}, %(option)s) resolve against sectionName; qualified
+// references (${section:option}) resolve against the named section;
+// ${env:VAR} resolves against the environment. chain records the
+// "section:option" path taken so far, for cycle detection.
+func (ini *INI) expandValue(sectionName, value string, chain []string, depth int) (string, error) {
+	var firstErr error
+
+	replace := func(ref string, resolve func(string) (string, error)) string {
+		if firstErr != nil {
+			return ref
+		}
+		resolved, err := resolve(ref)
+		if err != nil {
+			firstErr = err
+			return ref
+		}
+		return resolved
+	}
+
+	result := braceRefRegex.ReplaceAllStringFunc(value, func(match string) string {
+		inner := match[2 : len(match)-1]
+		return replace(inner, func(inner string) (string, error) {
+			if rest, ok := strings.CutPrefix(inner, "env:"); ok {
+				return os.Getenv(rest), nil
+			}
+			refSection, refOption := sectionName, inner
+			if idx := strings.Index(inner, ":"); idx >= 0 {
+				refSection, refOption = inner[:idx], inner[idx+1:]
+			}
+			return ini.resolveRef(refSection, refOption, chain, depth+1)
+		})
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+
+	result = percentRefRegex.ReplaceAllStringFunc(result, func(match string) string {
+		name := match[2 : len(match)-2]
+		return replace(name, func(name string) (string, error) {
+			return ini.resolveRef(sectionName, name, chain, depth+1)
+		})
+	})
+	if firstErr != nil {
+		return "", firstErr
+	}
+	return result, nil
+}
+
+// resolveRef resolves a single "section:option" reference, detecting cycles
+// via chain and enforcing the recursion limit.
+func (ini *INI) resolveRef(sectionName, optionName string, chain []string, depth int) (string, error) {
+	key := sectionName + ":" + optionName
+	for _, seen := range chain {
+		if seen == key {
+			return "", fmt.Errorf("goini: interpolation cycle detected: %s→%s", strings.Join(chain, "→"), key)
+		}
+	}
+
+	limit := ini.recursionLimit
+	if limit <= 0 {
+		limit = defaultRecursionLimit
+	}
+	if depth > limit {
+		return "", fmt.Errorf("goini: interpolation recursion limit (%d) exceeded resolving %s", limit, key)
+	}
+
+	raw, found := ini.getRaw(sectionName, optionName)
+	if !found {
+		return "", fmt.Errorf("goini: interpolation reference %q not found", key)
+	}
+	return ini.expandValue(sectionName, raw, append(append([]string{}, chain...), key), depth)
+}
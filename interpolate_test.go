@@ -0,0 +1,151 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExpandBareAndQualifiedRefs(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "host", "localhost")
+	ini.AddOption("section1", "url", "http://${host}/")
+	ini.AddOption("section2", "full", "${section1:url}index.html")
+	ini.Expand()
+
+	val, found := ini.GetOption("section1", "url")
+	assertTrue(t, found)
+	assertEquals(t, "http://localhost/", val)
+
+	val, found = ini.GetOption("section2", "full")
+	assertTrue(t, found)
+	assertEquals(t, "http://localhost/index.html", val)
+}
+
+func TestExpandPercentStyleRefs(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "name", "world")
+	ini.AddOption("section1", "greeting", "hello %(name)s")
+	ini.Expand()
+
+	val, found := ini.GetOption("section1", "greeting")
+	assertTrue(t, found)
+	assertEquals(t, "hello world", val)
+}
+
+func TestExpandEnvRef(t *testing.T) {
+	os.Setenv("GOINI_TEST_VAR", "envvalue")
+	defer os.Unsetenv("GOINI_TEST_VAR")
+
+	ini := NewINI(true)
+	ini.AddOption("section1", "option1", "${env:GOINI_TEST_VAR}")
+	ini.Expand()
+
+	val, found := ini.GetOption("section1", "option1")
+	assertTrue(t, found)
+	assertEquals(t, "envvalue", val)
+}
+
+func TestExpandCycleFallsBackToRaw(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "a", "${b}")
+	ini.AddOption("section1", "b", "${a}")
+	ini.Expand()
+
+	val, found := ini.GetOption("section1", "a")
+	assertTrue(t, found)
+	assertEquals(t, "${b}", val)
+
+	_, _, err := ini.ExpandOption("section1", "a")
+	assertError(t, err)
+	assertTrue(t, strings.Contains(err.Error(), "cycle"))
+}
+
+func TestGetOptionRawBypassesExpansion(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "host", "localhost")
+	ini.AddOption("section1", "url", "http://${host}/")
+	ini.Expand()
+
+	val, found := ini.GetOptionRaw("section1", "url")
+	assertTrue(t, found)
+	assertEquals(t, "http://${host}/", val)
+}
+
+func TestDefaultSectionFallback(t *testing.T) {
+	data := "[DEFAULT]\ntimeout = 30\n\n[section1]\noption1 = value1\n"
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	val, found := ini.GetOption("section1", "timeout")
+	assertTrue(t, found)
+	assertEquals(t, "30", val)
+
+	assertTrue(t, ini.HasOption("section1", "timeout"))
+}
+
+func TestSectionOverridesDefaultSection(t *testing.T) {
+	data := "[DEFAULT]\ntimeout = 30\n\n[section1]\ntimeout = 60\n"
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	val, found := ini.GetOption("section1", "timeout")
+	assertTrue(t, found)
+	assertEquals(t, "60", val)
+}
+
+func TestWriteDoesNotBakeInInterpolatedValues(t *testing.T) {
+	os.Setenv("GOINI_TEST_VAR", "envvalue")
+	defer os.Unsetenv("GOINI_TEST_VAR")
+
+	ini := NewINI(true)
+	ini.AddOption("section1", "host", "localhost")
+	ini.AddOption("section1", "url", "http://${host}/")
+	ini.AddOption("section1", "b", "${env:GOINI_TEST_VAR}/x")
+	ini.Expand()
+
+	val, found := ini.GetOption("section1", "url")
+	assertTrue(t, found)
+	assertEquals(t, "http://localhost/", val)
+
+	var sb strings.Builder
+	err := Write(ini, &sb)
+	assertNoError(t, err)
+	out := sb.String()
+	assertTrue(t, strings.Contains(out, "url = http://${host}/"))
+	assertTrue(t, strings.Contains(out, "b = ${env:GOINI_TEST_VAR}/x"))
+}
+
+func TestParserInterpolateOption(t *testing.T) {
+	data := "[section1]\nhost = localhost\nurl = http://${host}/\n"
+	p := NewParser(ParserOptions{Interpolate: true})
+	ini := NewINI(true)
+	err := p.Parse(strings.NewReader(data), ini)
+	assertNoError(t, err)
+
+	val, found := ini.GetOption("section1", "url")
+	assertTrue(t, found)
+	assertEquals(t, "http://localhost/", val)
+}
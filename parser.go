@@ -0,0 +1,344 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	sectionRegex = regexp.MustCompile(`^\[(.*)\]$`)
+	assignRegex  = regexp.MustCompile(`^([^=]+)=(.*)$`)
+)
+
+// includeOption is the option name that triggers recursive parsing of
+// another INI file, e.g. "include = conf.d/*.ini".
+const includeOption = "include"
+
+// errNoMoreLines is a sentinel returned by readLogicalLine when the input is
+// fully consumed and there is no trailing physical line left to process.
+var errNoMoreLines = errors.New("goini: no more lines")
+
+// ParserOptions configures the syntax a Parser accepts.
+type ParserOptions struct {
+	// AllowInlineComments enables stripping a trailing "# ..." or "; ..."
+	// comment following a value on the same line. It is off by default
+	// because values may legitimately contain '#' or ';'.
+	AllowInlineComments bool
+
+	// BaseDir is the directory that relative "include" paths are resolved
+	// against when parsing from an io.Reader rather than a file. It is
+	// ignored by ParseFile, which always resolves includes relative to the
+	// directory of the file being parsed.
+	BaseDir string
+
+	// Interpolate enables ${...}/%(...)s value interpolation on the parsed
+	// INI (see INI.Expand). Off by default.
+	Interpolate bool
+
+	// RecursionLimit bounds how many nested interpolation references are
+	// followed before giving up. Zero uses the default of 10.
+	RecursionLimit int
+
+	// DefaultSection overrides the parsed INI's DefaultSection. Empty uses
+	// the default of "DEFAULT".
+	DefaultSection string
+}
+
+// Parser parses INI data according to its ParserOptions. A Parser can be
+// reused to parse multiple times; include cycle detection only tracks the
+// files being parsed within a single Parse or ParseFile call.
+type Parser struct {
+	opts    ParserOptions
+	baseDir string
+	visited map[string]bool
+}
+
+// NewParser creates a new Parser with the specified options.
+func NewParser(opts ParserOptions) *Parser {
+	return &Parser{
+		opts:    opts,
+		baseDir: opts.BaseDir,
+		visited: map[string]bool{},
+	}
+}
+
+// Parse parses INI data from reader into ini. Relative "include" paths are
+// resolved against opts.BaseDir.
+func (p *Parser) Parse(reader io.Reader, ini *INI) error {
+	p.applyOptions(ini)
+	return p.parseNamed("", reader, ini)
+}
+
+// applyOptions carries Interpolate/RecursionLimit/DefaultSection from
+// p.opts onto ini.
+func (p *Parser) applyOptions(ini *INI) {
+	if p.opts.Interpolate {
+		ini.Expand()
+	}
+	if p.opts.RecursionLimit > 0 {
+		ini.recursionLimit = p.opts.RecursionLimit
+	}
+	if p.opts.DefaultSection != "" {
+		ini.DefaultSection = p.opts.DefaultSection
+	}
+}
+
+// ParseFile parses the INI file at path into ini. Relative "include" paths
+// in that file are resolved against path's directory.
+func (p *Parser) ParseFile(path string, ini *INI) error {
+	p.applyOptions(ini)
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if p.visited[abs] {
+		return fmt.Errorf("goini: circular include detected: %s", path)
+	}
+	p.visited[abs] = true
+	defer delete(p.visited, abs)
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	prevBaseDir := p.baseDir
+	p.baseDir = filepath.Dir(abs)
+	defer func() { p.baseDir = prevBaseDir }()
+
+	return p.parseNamed(path, file, ini)
+}
+
+func (p *Parser) parseNamed(name string, reader io.Reader, ini *INI) error {
+	bufin, ok := reader.(*bufio.Reader)
+	if !ok {
+		bufin = bufio.NewReader(reader)
+	}
+
+	section := ""
+	lineNum := 0
+	// pending holds comment lines seen since the last blank/option/section
+	// line. It is attached to the next option or section as its leading
+	// comment; a blank line in between breaks the attachment and the
+	// buffered lines are flushed as standalone comments instead.
+	var pending []string
+	flushPending := func() {
+		for _, text := range pending {
+			ini.appendCommentNode(text)
+		}
+		pending = nil
+	}
+
+	for {
+		line, startLine, eof, err := p.readLogicalLine(bufin, &lineNum)
+		if err == errNoMoreLines {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			flushPending()
+			ini.appendBlankNode()
+			if eof {
+				return nil
+			}
+			continue
+		}
+		if trimmed[0] == ';' || trimmed[0] == '#' {
+			pending = append(pending, trimmed)
+			if eof {
+				flushPending()
+				return nil
+			}
+			continue
+		}
+
+		if groups := assignRegex.FindStringSubmatch(trimmed); groups != nil {
+			key := strings.TrimSpace(groups[1])
+			value, verr := p.parseValue(groups[2])
+			if verr != nil {
+				return ErrSyntax{File: name, Line: startLine, Source: trimmed}
+			}
+			if key == includeOption {
+				pending = nil
+				if err := p.include(value, ini); err != nil {
+					return err
+				}
+			} else {
+				ini.addParsedOption(section, key, value, trimmed)
+				if len(pending) > 0 {
+					ini.SetOptionComment(section, key, strings.Join(pending, "\n"))
+					pending = nil
+				}
+			}
+		} else if groups := sectionRegex.FindStringSubmatch(trimmed); groups != nil {
+			section = strings.TrimSpace(groups[1])
+			ini.AddSection(section)
+			if len(pending) > 0 {
+				ini.SetSectionComment(section, strings.Join(pending, "\n"))
+				pending = nil
+			}
+		} else {
+			return ErrSyntax{File: name, Line: startLine, Source: trimmed}
+		}
+
+		if eof {
+			return nil
+		}
+	}
+}
+
+// readLogicalLine reads a single physical line from bufin, joining
+// subsequent lines while the current one ends with an unescaped trailing
+// backslash (a line continuation). *lineNum is advanced by the number of
+// physical lines consumed, and the returned startLine is the line number the
+// logical line began on, for use in error messages. eof reports whether the
+// underlying reader is exhausted; when eof is true and no bytes at all were
+// read, the caller has reached the very end of the input with no trailing
+// line to process.
+func (p *Parser) readLogicalLine(bufin *bufio.Reader, lineNum *int) (string, int, bool, error) {
+	var sb strings.Builder
+	startLine := *lineNum + 1
+	for {
+		raw, err := bufin.ReadString('\n')
+		eof := err == io.EOF
+		if err != nil && !eof {
+			return "", startLine, false, err
+		}
+		if len(raw) > 0 {
+			*lineNum++
+		} else if eof && sb.Len() == 0 {
+			return "", startLine, true, errNoMoreLines
+		}
+		chunk := strings.TrimRight(raw, "\r\n")
+		if !eof && (len(chunk)-len(strings.TrimRight(chunk, "\\")))%2 == 1 {
+			sb.WriteString(chunk[:len(chunk)-1])
+			continue
+		}
+		sb.WriteString(chunk)
+		return sb.String(), startLine, eof, nil
+	}
+}
+
+// parseValue interprets raw as found after the '=' of an option line: it
+// trims surrounding whitespace, unquotes a double- or single-quoted value
+// (processing \n, \t, \\, \" and \' escapes), and, when AllowInlineComments
+// is set, strips a trailing "# ..." or "; ..." comment.
+func (p *Parser) parseValue(raw string) (string, error) {
+	trimmed := strings.TrimSpace(raw)
+	if len(trimmed) >= 2 && (trimmed[0] == '"' || trimmed[0] == '\'') {
+		quote := trimmed[0]
+		end := findClosingQuote(trimmed, quote)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value: %s", raw)
+		}
+		return unquote(trimmed[1:end]), nil
+	}
+	if p.opts.AllowInlineComments {
+		if idx := strings.IndexAny(trimmed, "#;"); idx >= 0 {
+			trimmed = strings.TrimSpace(trimmed[:idx])
+		}
+	}
+	return trimmed, nil
+}
+
+// include resolves pattern (optionally a glob) relative to p.baseDir and
+// recursively parses every match into ini.
+func (p *Parser) include(pattern string, ini *INI) error {
+	fullPattern := pattern
+	if !filepath.IsAbs(pattern) && p.baseDir != "" {
+		fullPattern = filepath.Join(p.baseDir, pattern)
+	}
+	matches, err := filepath.Glob(fullPattern)
+	if err != nil {
+		return err
+	}
+	if len(matches) == 0 {
+		return fmt.Errorf("goini: include %q matched no files", pattern)
+	}
+	sort.Strings(matches)
+	for _, match := range matches {
+		if err := p.ParseFile(match, ini); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findClosingQuote returns the index within s (which starts with quote) of
+// the matching unescaped closing quote, or -1 if none is found.
+func findClosingQuote(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == quote {
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote processes the \n, \t, \\, \" and \' escape sequences inside inner,
+// the content of a quoted value with the surrounding quote characters
+// already stripped.
+func unquote(inner string) string {
+	var b strings.Builder
+	for i := 0; i < len(inner); i++ {
+		c := inner[i]
+		if c == '\\' && i+1 < len(inner) {
+			i++
+			switch inner[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '\\':
+				b.WriteByte('\\')
+			case '"':
+				b.WriteByte('"')
+			case '\'':
+				b.WriteByte('\'')
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(inner[i])
+			}
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,152 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseQuotedValues(t *testing.T) {
+	data := "[section1]\n" +
+		"option1 = \"  hello world  \"\n" +
+		"option2 = 'raw \\n value'\n" +
+		"option3 = \"line1\\nline2\\ttabbed\"\n"
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	val, found := ini.GetOption("section1", "option1")
+	assertTrue(t, found)
+	assertEquals(t, "  hello world  ", val)
+
+	val, found = ini.GetOption("section1", "option2")
+	assertTrue(t, found)
+	assertEquals(t, "raw \n value", val)
+
+	val, found = ini.GetOption("section1", "option3")
+	assertTrue(t, found)
+	assertEquals(t, "line1\nline2\ttabbed", val)
+}
+
+func TestParseLineContinuation(t *testing.T) {
+	data := "[section1]\n" +
+		"option1 = hello \\\nworld\n"
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	val, found := ini.GetOption("section1", "option1")
+	assertTrue(t, found)
+	assertEquals(t, "hello world", val)
+}
+
+func TestParseInlineComments(t *testing.T) {
+	data := "[section1]\n" +
+		"option1 = value1 # a trailing comment\n" +
+		"option2 = value2 ; another comment\n"
+
+	p := NewParser(ParserOptions{AllowInlineComments: true})
+	ini := NewINI(true)
+	err := p.Parse(strings.NewReader(data), ini)
+	assertNoError(t, err)
+
+	val, found := ini.GetOption("section1", "option1")
+	assertTrue(t, found)
+	assertEquals(t, "value1", val)
+
+	val, found = ini.GetOption("section1", "option2")
+	assertTrue(t, found)
+	assertEquals(t, "value2", val)
+}
+
+func TestParseInlineCommentsDisabledByDefault(t *testing.T) {
+	data := "[section1]\noption1 = value1 # not a comment\n"
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	val, found := ini.GetOption("section1", "option1")
+	assertTrue(t, found)
+	assertEquals(t, "value1 # not a comment", val)
+}
+
+func TestParseInclude(t *testing.T) {
+	dir := t.TempDir()
+	includedPath := filepath.Join(dir, "included.ini")
+	mainPath := filepath.Join(dir, "main.ini")
+
+	err := os.WriteFile(includedPath, []byte("[included]\noption1 = value1\n"), 0644)
+	assertNoError(t, err)
+	err = os.WriteFile(mainPath, []byte("[main]\noption1 = value1\n\ninclude = included.ini\n"), 0644)
+	assertNoError(t, err)
+
+	ini, err := ReadFile(mainPath, true)
+	assertNoError(t, err)
+
+	val, found := ini.GetOption("main", "option1")
+	assertTrue(t, found)
+	assertEquals(t, "value1", val)
+
+	val, found = ini.GetOption("included", "option1")
+	assertTrue(t, found)
+	assertEquals(t, "value1", val)
+}
+
+func TestParseIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	aPath := filepath.Join(dir, "a.ini")
+	bPath := filepath.Join(dir, "b.ini")
+
+	err := os.WriteFile(aPath, []byte("[a]\ninclude = b.ini\n"), 0644)
+	assertNoError(t, err)
+	err = os.WriteFile(bPath, []byte("[b]\ninclude = a.ini\n"), 0644)
+	assertNoError(t, err)
+
+	_, err = ReadFile(aPath, true)
+	assertError(t, err)
+}
+
+func TestParseSyntaxErrorIncludesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "bad.ini")
+	err := os.WriteFile(path, []byte("not valid ini\n"), 0644)
+	assertNoError(t, err)
+
+	_, err = ReadFile(path, true)
+	assertError(t, err)
+	syntaxErr, ok := err.(ErrSyntax)
+	assertTrue(t, ok)
+	assertEquals(t, path, syntaxErr.File)
+}
+
+func TestWriteQuotesValuesThatNeedIt(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "option1", "  has spaces  ")
+	ini.AddOption("section1", "option2", "has#hash")
+
+	var sb strings.Builder
+	err := Write(ini, &sb)
+	assertNoError(t, err)
+	assertTrue(t, strings.Contains(sb.String(), `option1 = "  has spaces  "`))
+	assertTrue(t, strings.Contains(sb.String(), `option2 = "has#hash"`))
+}
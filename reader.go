@@ -0,0 +1,43 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"io"
+)
+
+// Read reads an INI from an io.Reader. Passing ordered parameter true will preserve the
+// order. Preserving the order will have some performance overhead.
+func Read(reader io.Reader, ordered bool) (*INI, error) {
+	ini := NewINI(ordered)
+	err := NewParser(ParserOptions{}).Parse(reader, ini)
+	return ini, err
+}
+
+// ReadFile reads an INI from a file. Passing ordered parameter true will preserve the
+// order. Preserving the order will have some performance overhead.
+func ReadFile(path string, ordered bool) (*INI, error) {
+	ini := NewINI(ordered)
+	err := NewParser(ParserOptions{}).ParseFile(path, ini)
+	return ini, err
+}
@@ -0,0 +1,181 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// WriteOptions configures how Write renders an INI. AlignEquals and
+// KeyValueSeparator do not apply to parsed options whose value is unchanged,
+// which are written from their original source line.
+type WriteOptions struct {
+	// IncludeComments controls whether comments recorded by the parser, or
+	// attached via SetSectionComment/SetOptionComment, are written back out.
+	IncludeComments bool
+
+	// AlignEquals pads option names within a section so that every
+	// KeyValueSeparator lines up in the same column.
+	AlignEquals bool
+
+	// KeyValueSeparator is written between an option's name and its value,
+	// e.g. "=" or " = ". It defaults to " = " when empty.
+	KeyValueSeparator string
+}
+
+// DefaultWriteOptions returns the WriteOptions that Write itself uses.
+func DefaultWriteOptions() WriteOptions {
+	return WriteOptions{IncludeComments: true, KeyValueSeparator: " = "}
+}
+
+// Write writes an INI into an io.Writer using DefaultWriteOptions(). Any
+// comments, blank lines and ordering recorded when the INI was parsed (or
+// set via SetSectionComment/SetOptionComment) are preserved.
+func Write(ini *INI, writer io.Writer) error {
+	return WriteWithOptions(ini, writer, DefaultWriteOptions())
+}
+
+// WriteFile writes an INI into a file using DefaultWriteOptions().
+func WriteFile(ini *INI, path string) error {
+	return WriteFileWithOptions(ini, path, DefaultWriteOptions())
+}
+
+// WriteWithOptions writes an INI into an io.Writer according to opts.
+func WriteWithOptions(ini *INI, writer io.Writer, opts WriteOptions) error {
+	sep := opts.KeyValueSeparator
+	if sep == "" {
+		sep = " = "
+	}
+	for _, n := range ini.nodes {
+		switch n.kind {
+		case blankNode:
+			fmt.Fprintln(writer)
+		case commentNode:
+			if opts.IncludeComments {
+				fmt.Fprintln(writer, n.text)
+			}
+		case sectionNode:
+			if opts.IncludeComments {
+				writeComment(writer, ini.SectionComment(n.section))
+			}
+			fmt.Fprintln(writer, "["+n.section+"]")
+		case optionNode:
+			raw, ok := ini.optionValueAt(n.section, n.option, n.valueIndex)
+			if !ok {
+				continue
+			}
+			if opts.IncludeComments {
+				writeComment(writer, ini.OptionComment(n.section, n.option))
+			}
+			// Write always emits the raw, unexpanded value: interpolation
+			// (INI.Expand) resolves ${...}/%(...)s references lazily at
+			// GetOption time and must not be baked into the serialized file.
+			//
+			// An option that still holds the value it was parsed with is
+			// written back using its original source line verbatim, rather
+			// than through KeyValueSeparator/quoteIfNeeded, so an unmutated
+			// read-write cycle doesn't alter its formatting.
+			if n.origLine != "" && raw == n.origValue {
+				fmt.Fprintln(writer, n.origLine)
+				continue
+			}
+			key := n.option
+			if opts.AlignEquals {
+				if width := maxOptionNameWidth(ini, n.section); len(key) < width {
+					key += strings.Repeat(" ", width-len(key))
+				}
+			}
+			fmt.Fprintf(writer, "%s%s%s\n", key, sep, quoteIfNeeded(raw))
+		}
+	}
+	return nil
+}
+
+// WriteFileWithOptions writes an INI into a file according to opts.
+func WriteFileWithOptions(ini *INI, path string, opts WriteOptions) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteWithOptions(ini, file, opts)
+}
+
+func writeComment(writer io.Writer, comment string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		fmt.Fprintln(writer, line)
+	}
+}
+
+func maxOptionNameWidth(ini *INI, sectionName string) int {
+	width := 0
+	for _, option := range ini.Options(sectionName) {
+		if len(option) > width {
+			width = len(option)
+		}
+	}
+	return width
+}
+
+// quoteIfNeeded returns value, quoted if it contains leading/trailing
+// whitespace or characters that would otherwise be misread on the next
+// parse: '#', ';', '=', or a newline.
+func quoteIfNeeded(value string) string {
+	if !needsQuoting(value) {
+		return value
+	}
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+func needsQuoting(value string) bool {
+	if value == "" {
+		return false
+	}
+	if strings.TrimSpace(value) != value {
+		return true
+	}
+	return strings.ContainsAny(value, "#;=\n")
+}
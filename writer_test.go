@@ -0,0 +1,124 @@
+// The MIT License (MIT)
+//
+// Copyright (c) 2016 Fredy Wijaya
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package goini
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoundTripPreservesCommentsAndBlankLines(t *testing.T) {
+	data := "; top-of-file comment\n" +
+		"\n" +
+		"; a comment attached to section1\n" +
+		"[section1]\n" +
+		"; a comment attached to option1\n" +
+		"option1 = value1\n" +
+		"\n" +
+		"option2 = value2\n"
+
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	assertEquals(t, "; a comment attached to section1", ini.SectionComment("section1"))
+	assertEquals(t, "; a comment attached to option1", ini.OptionComment("section1", "option1"))
+
+	var sb strings.Builder
+	err = Write(ini, &sb)
+	assertNoError(t, err)
+	assertEquals(t, data, sb.String())
+}
+
+func TestWriteWithoutComments(t *testing.T) {
+	data := "; a comment\n[section1]\noption1 = value1\n"
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	var sb strings.Builder
+	err = WriteWithOptions(ini, &sb, WriteOptions{IncludeComments: false, KeyValueSeparator: " = "})
+	assertNoError(t, err)
+	assertFalse(t, strings.Contains(sb.String(), "a comment"))
+}
+
+func TestWriteAlignEquals(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "a", "1")
+	ini.AddOption("section1", "longer", "2")
+
+	var sb strings.Builder
+	err := WriteWithOptions(ini, &sb, WriteOptions{AlignEquals: true, KeyValueSeparator: " = "})
+	assertNoError(t, err)
+
+	lines := strings.Split(strings.TrimRight(sb.String(), "\n"), "\n")
+	idxA := strings.Index(lines[1], "=")
+	idxLonger := strings.Index(lines[2], "=")
+	assertEquals(t, idxA, idxLonger)
+}
+
+func TestWriteKeyValueSeparator(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "option1", "value1")
+
+	var sb strings.Builder
+	err := WriteWithOptions(ini, &sb, WriteOptions{KeyValueSeparator: "="})
+	assertNoError(t, err)
+	assertTrue(t, strings.Contains(sb.String(), "option1=value1"))
+}
+
+func TestSetOptionAndSectionComment(t *testing.T) {
+	ini := NewINI(true)
+	ini.AddOption("section1", "option1", "value1")
+	ini.SetSectionComment("section1", "; section comment")
+	ini.SetOptionComment("section1", "option1", "; option comment")
+
+	var sb strings.Builder
+	err := Write(ini, &sb)
+	assertNoError(t, err)
+
+	out := sb.String()
+	assertTrue(t, strings.Contains(out, "; section comment\n[section1]"))
+	assertTrue(t, strings.Contains(out, "; option comment\noption1"))
+}
+
+func TestWritePreservesUnmutatedValuesVerbatim(t *testing.T) {
+	data := "[section1]\nk=v\nq = 'hello world'\nr = a=b\n"
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+
+	var sb strings.Builder
+	err = Write(ini, &sb)
+	assertNoError(t, err)
+	assertEquals(t, data, sb.String())
+}
+
+func TestWriteNormalizesOnlyMutatedValues(t *testing.T) {
+	data := "[section1]\nk=v\nq=w\n"
+	ini, err := Read(strings.NewReader(data), true)
+	assertNoError(t, err)
+	ini.AddOption("section1", "k", "changed")
+
+	var sb strings.Builder
+	err = Write(ini, &sb)
+	assertNoError(t, err)
+	assertEquals(t, "[section1]\nk = changed\nq=w\n", sb.String())
+}